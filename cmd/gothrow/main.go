@@ -0,0 +1,586 @@
+// Command gothrow reports ignored errors and offers fixes that surface
+// them, via the golang.org/x/tools/go/analysis framework. gothrow.Analyzer
+// is an ordinary *analysis.Analyzer, so it can also be composed into
+// another tool's own multichecker without going through this binary at
+// all.
+//
+// Invoked as `go vet -vettool=$(which gothrow)`, this binary speaks the
+// unitchecker protocol via singlechecker.Main instead of the driver
+// below, since that's the protocol go vet's build-system integration
+// expects.
+//
+// Run directly without -fix, it never touches the working tree: it
+// loads the packages under the given directory, runs the gothrow
+// Analyzer, and reports what it found in the format selected by
+// -format (text, json, or sarif), which makes it usable from
+// pre-commit hooks and code-review bots. -n and -diff are explicit
+// synonyms for that default dry-run behavior, for callers that want to
+// assert in a script that nothing was written; -diff additionally
+// prints each fix's pre- and post-image snippets in the text report
+// (the json report always carries them as "before" and "after", with
+// or without -diff). Pass -fix to apply
+// the suggested fixes and rewrite the files on disk instead.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/format"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/iondodon/gothrow"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/analysis/singlechecker"
+	"golang.org/x/tools/go/packages"
+)
+
+// driverFlags parses this binary's own dry-run/-fix/-format flags. It's
+// kept separate from gothrow.Analyzer.Flags so they don't leak into
+// singlechecker's flag registration (and collide with its built-in
+// -fix) when this binary is invoked as a go vet -vettool; main merges
+// in whatever gothrow.Analyzer.Flags itself defines, such as -policy,
+// so both entry points accept it.
+var driverFlags = flag.NewFlagSet("gothrow", flag.ExitOnError)
+
+var (
+	fix       = driverFlags.Bool("fix", false, "apply suggested fixes and rewrite the files on disk")
+	dryRun    = driverFlags.Bool("n", false, "dry run: report diagnostics without writing any files (alias -diff)")
+	diff      = driverFlags.Bool("diff", false, "like -n, and also include each fix's before/after snippet in the text report (json always includes them)")
+	outFormat = driverFlags.String("format", "text", "output format for the report: text, json, or sarif")
+)
+
+// isUnitcheckerInvocation reports whether args matches the command
+// lines go vet's -vettool integration issues: a probe for the tool's
+// version (-V=full) or flag set (-flags), or the single *.cfg file
+// describing the compilation unit to analyze. Any of these should be
+// handled by singlechecker.Main's unitchecker support rather than the
+// dry-run/-fix driver below.
+func isUnitcheckerInvocation(args []string) bool {
+	for _, a := range args {
+		if a == "-flags" || strings.HasPrefix(a, "-V=") {
+			return true
+		}
+	}
+	return len(args) == 1 && strings.HasSuffix(args[0], ".cfg")
+}
+
+func main() {
+	if isUnitcheckerInvocation(os.Args[1:]) {
+		singlechecker.Main(gothrow.Analyzer)
+		return
+	}
+
+	gothrow.Analyzer.Flags.VisitAll(func(f *flag.Flag) {
+		if driverFlags.Lookup(f.Name) == nil {
+			driverFlags.Var(f.Value, f.Name, f.Usage)
+		}
+	})
+	driverFlags.Parse(os.Args[1:])
+	args := driverFlags.Args()
+
+	dir := "."
+	if len(args) > 0 {
+		dir = args[0]
+	}
+
+	switch *outFormat {
+	case "text", "json", "sarif":
+	default:
+		fmt.Fprintf(os.Stderr, "gothrow: unknown -format %q (want text, json, or sarif)\n", *outFormat)
+		os.Exit(2)
+	}
+
+	findings, err := analyze(dir)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "gothrow:", err)
+		os.Exit(1)
+	}
+
+	// Snapshot before/after snippets from the pre-fix file content before
+	// applyFixes rewrites anything: snippet() slices files by the byte
+	// offsets recorded in each Diagnostic, and applyFixes moves every
+	// offset after its first edit in a file, so a snippet computed after
+	// writing no longer lines up with the diagnostic it describes.
+	snippets := computeSnippets(findings)
+
+	if *fix && !*dryRun && !*diff {
+		if err := applyFixes(findings); err != nil {
+			fmt.Fprintln(os.Stderr, "gothrow:", err)
+			os.Exit(1)
+		}
+	}
+
+	switch *outFormat {
+	case "json":
+		emitJSON(findings, snippets)
+	case "sarif":
+		emitSARIF(findings)
+	default:
+		emitText(findings, snippets, *diff)
+	}
+
+	if len(findings) > 0 {
+		os.Exit(3)
+	}
+}
+
+// finding pairs one diagnostic with the package it was reported in, so
+// later stages can reach pkg.Fset and the file's on-disk content without
+// re-deriving them.
+type finding struct {
+	pkg  *packages.Package
+	diag analysis.Diagnostic
+}
+
+// analyze loads the packages under dir and runs the gothrow Analyzer
+// over each of them, collecting every reported Diagnostic. It never
+// writes anything; writing back to disk, when requested, is a separate
+// step driven by the result.
+func analyze(dir string) ([]finding, error) {
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedCompiledGoFiles |
+			packages.NeedSyntax | packages.NeedTypes | packages.NeedTypesInfo,
+		Dir: dir,
+	}
+	pkgs, err := packages.Load(cfg, "./...")
+	if err != nil {
+		return nil, fmt.Errorf("loading packages: %w", err)
+	}
+
+	var findings []finding
+	for _, pkg := range pkgs {
+		for _, pkgErr := range pkg.Errors {
+			fmt.Fprintln(os.Stderr, pkgErr)
+		}
+
+		diags, err := runAnalyzer(pkg)
+		if err != nil {
+			return nil, fmt.Errorf("analyzing %s: %w", pkg.PkgPath, err)
+		}
+		for _, d := range diags {
+			findings = append(findings, finding{pkg: pkg, diag: d})
+		}
+	}
+
+	sort.Slice(findings, func(i, j int) bool {
+		pi := findings[i].pkg.Fset.Position(findings[i].diag.Pos)
+		pj := findings[j].pkg.Fset.Position(findings[j].diag.Pos)
+		if pi.Filename != pj.Filename {
+			return pi.Filename < pj.Filename
+		}
+		return pi.Offset < pj.Offset
+	})
+
+	return findings, nil
+}
+
+// runAnalyzer runs gothrow.Analyzer over a single loaded package,
+// standing in for the parts of the go/analysis driver machinery that
+// singlechecker would otherwise provide: gothrow.Analyzer only requires
+// inspect.Analyzer, so its result is the only thing that needs wiring up
+// by hand.
+func runAnalyzer(pkg *packages.Package) ([]analysis.Diagnostic, error) {
+	insResult, err := inspect.Analyzer.Run(&analysis.Pass{Fset: pkg.Fset, Files: pkg.Syntax})
+	if err != nil {
+		return nil, err
+	}
+
+	var diags []analysis.Diagnostic
+	pass := &analysis.Pass{
+		Analyzer:   gothrow.Analyzer,
+		Fset:       pkg.Fset,
+		Files:      pkg.Syntax,
+		Pkg:        pkg.Types,
+		TypesInfo:  pkg.TypesInfo,
+		TypesSizes: pkg.TypesSizes,
+		ResultOf:   map[*analysis.Analyzer]interface{}{inspect.Analyzer: insResult},
+		Report:     func(d analysis.Diagnostic) { diags = append(diags, d) },
+	}
+	if _, err := gothrow.Analyzer.Run(pass); err != nil {
+		return nil, err
+	}
+	return diags, nil
+}
+
+// applyFixes groups every finding's first SuggestedFix by file, applies
+// the edits back-to-front so earlier offsets stay valid, and writes the
+// gofmt'd result. It mirrors what singlechecker's own -fix support did
+// before cmd/gothrow took over package loading itself.
+func applyFixes(findings []finding) error {
+	type fileEdits struct {
+		fset  *token.FileSet
+		edits []analysis.TextEdit
+	}
+	byFile := make(map[string]*fileEdits)
+
+	for _, f := range findings {
+		if len(f.diag.SuggestedFixes) == 0 {
+			continue
+		}
+		for _, edit := range f.diag.SuggestedFixes[0].TextEdits {
+			name := f.pkg.Fset.Position(edit.Pos).Filename
+			fe, ok := byFile[name]
+			if !ok {
+				fe = &fileEdits{fset: f.pkg.Fset}
+				byFile[name] = fe
+			}
+			fe.edits = append(fe.edits, edit)
+		}
+	}
+
+	for name, fe := range byFile {
+		content, err := os.ReadFile(name)
+		if err != nil {
+			return err
+		}
+
+		edits := mergeEdits(name, fe.edits)
+
+		sort.Slice(edits, func(i, j int) bool { return edits[i].Pos > edits[j].Pos })
+		for _, edit := range edits {
+			lo := fe.fset.Position(edit.Pos).Offset
+			hi := fe.fset.Position(edit.End).Offset
+			content = append(content[:lo:lo], append(edit.NewText, content[hi:]...)...)
+		}
+
+		formatted, err := format.Source(content)
+		if err != nil {
+			// A result that gofmt can't format (for instance because it
+			// no longer parses) is written unformatted as it is; the next
+			// `go build` surfaces the problem.
+			formatted = content
+		}
+		if err := os.WriteFile(name, formatted, 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mergeEdits drops exact duplicates and overlapping edits from edits,
+// the way the standard go/analysis driver's own -fix support does, so
+// that two findings in the same file that each need to add the same
+// (or a colliding) import don't get spliced in twice. Duplicates are
+// dropped silently; true overlaps between distinct edits are dropped
+// with a warning on stderr, since applying either one and discarding
+// the other is safer than corrupting the file.
+func mergeEdits(name string, edits []analysis.TextEdit) []analysis.TextEdit {
+	sort.Slice(edits, func(i, j int) bool {
+		if edits[i].Pos != edits[j].Pos {
+			return edits[i].Pos < edits[j].Pos
+		}
+		return edits[i].End < edits[j].End
+	})
+
+	var merged []analysis.TextEdit
+	for _, edit := range edits {
+		if n := len(merged); n > 0 {
+			last := merged[n-1]
+			if edit.Pos == last.Pos && edit.End == last.End && string(edit.NewText) == string(last.NewText) {
+				continue
+			}
+			if edit.Pos < last.End {
+				fmt.Fprintf(os.Stderr, "gothrow: %s: skipping overlapping fix edit at offset %d\n", name, edit.Pos)
+				continue
+			}
+		}
+		merged = append(merged, edit)
+	}
+	return merged
+}
+
+// diagSnippet is a finding's before/after snippet, captured up front by
+// computeSnippets so that later stages (in particular -fix, which
+// rewrites files on disk) can't invalidate the byte offsets it reads.
+type diagSnippet struct {
+	before, after string
+}
+
+// computeSnippets reads each finding's before/after snippet from the
+// still-unmodified source. It must run before applyFixes: snippet()
+// reads files from disk and slices them with the offsets recorded on
+// the Diagnostic, and applyFixes's writes shift every offset after its
+// first edit in a file out from under those positions.
+func computeSnippets(findings []finding) []diagSnippet {
+	snippets := make([]diagSnippet, len(findings))
+	for i, f := range findings {
+		before, after := snippet(f.pkg, f.diag)
+		snippets[i] = diagSnippet{before: before, after: after}
+	}
+	return snippets
+}
+
+// snippet returns the pre-image source text for d, and the post-image
+// obtained by splicing in the edits from its first SuggestedFix that
+// fall within [d.Pos, d.End) — the fix's own import edit, if any, is
+// outside that range and left out, since it isn't part of what changed
+// at this statement. Callers that might run after applyFixes has
+// rewritten the file must use computeSnippets's cached result instead.
+func snippet(pkg *packages.Package, d analysis.Diagnostic) (before, after string) {
+	startOff := pkg.Fset.Position(d.Pos).Offset
+	endOff := pkg.Fset.Position(d.End).Offset
+	name := pkg.Fset.Position(d.Pos).Filename
+
+	content, err := os.ReadFile(name)
+	if err != nil {
+		return "", ""
+	}
+	before = string(content[startOff:endOff])
+	after = before
+
+	if len(d.SuggestedFixes) == 0 {
+		return before, after
+	}
+
+	var local []analysis.TextEdit
+	for _, e := range d.SuggestedFixes[0].TextEdits {
+		lo := pkg.Fset.Position(e.Pos).Offset
+		hi := pkg.Fset.Position(e.End).Offset
+		if lo >= startOff && hi <= endOff {
+			local = append(local, e)
+		}
+	}
+	sort.Slice(local, func(i, j int) bool { return local[i].Pos > local[j].Pos })
+
+	buf := []byte(after)
+	for _, e := range local {
+		lo := pkg.Fset.Position(e.Pos).Offset - startOff
+		hi := pkg.Fset.Position(e.End).Offset - startOff
+		buf = append(buf[:lo:lo], append(e.NewText, buf[hi:]...)...)
+	}
+	return before, string(buf)
+}
+
+func emitText(findings []finding, snippets []diagSnippet, withDiff bool) {
+	for i, f := range findings {
+		pos := f.pkg.Fset.Position(f.diag.Pos)
+		fmt.Printf("%s: %s\n", pos, f.diag.Message)
+		if withDiff {
+			fmt.Printf("  - %s\n  + %s\n", snippets[i].before, snippets[i].after)
+		}
+	}
+}
+
+type jsonRange struct {
+	StartLine int `json:"start_line"`
+	StartCol  int `json:"start_col"`
+	EndLine   int `json:"end_line"`
+	EndCol    int `json:"end_col"`
+}
+
+type jsonEdit struct {
+	StartLine int    `json:"start_line"`
+	StartCol  int    `json:"start_col"`
+	EndLine   int    `json:"end_line"`
+	EndCol    int    `json:"end_col"`
+	NewText   string `json:"new_text"`
+}
+
+type jsonSuggestedFix struct {
+	Message string     `json:"message"`
+	Edits   []jsonEdit `json:"edits"`
+}
+
+type jsonDiagnostic struct {
+	File         string            `json:"file"`
+	Range        jsonRange         `json:"range"`
+	Message      string            `json:"message"`
+	Before       string            `json:"before,omitempty"`
+	After        string            `json:"after,omitempty"`
+	SuggestedFix *jsonSuggestedFix `json:"suggested_fix,omitempty"`
+}
+
+func toJSONDiagnostic(f finding, snip diagSnippet) jsonDiagnostic {
+	fset := f.pkg.Fset
+	start, end := fset.Position(f.diag.Pos), fset.Position(f.diag.End)
+
+	jd := jsonDiagnostic{
+		File: start.Filename,
+		Range: jsonRange{
+			StartLine: start.Line, StartCol: start.Column,
+			EndLine: end.Line, EndCol: end.Column,
+		},
+		Message: f.diag.Message,
+	}
+	jd.Before, jd.After = snip.before, snip.after
+
+	if len(f.diag.SuggestedFixes) > 0 {
+		fix := f.diag.SuggestedFixes[0]
+		sf := &jsonSuggestedFix{Message: fix.Message}
+		for _, e := range fix.TextEdits {
+			es, ee := fset.Position(e.Pos), fset.Position(e.End)
+			sf.Edits = append(sf.Edits, jsonEdit{
+				StartLine: es.Line, StartCol: es.Column,
+				EndLine: ee.Line, EndCol: ee.Column,
+				NewText: string(e.NewText),
+			})
+		}
+		jd.SuggestedFix = sf
+	}
+	return jd
+}
+
+func emitJSON(findings []finding, snippets []diagSnippet) {
+	diags := make([]jsonDiagnostic, len(findings))
+	for i, f := range findings {
+		diags[i] = toJSONDiagnostic(f, snippets[i])
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(diags); err != nil {
+		fmt.Fprintln(os.Stderr, "gothrow:", err)
+	}
+}
+
+// SARIF 2.1.0 types, trimmed to the fields gothrow actually populates.
+// See https://docs.oasis-open.org/sarif/sarif/v2.1.0/.
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name string `json:"name"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn"`
+	EndLine     int `json:"endLine"`
+	EndColumn   int `json:"endColumn"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifInsertedContent struct {
+	Text string `json:"text"`
+}
+
+type sarifReplacement struct {
+	DeletedRegion   sarifRegion          `json:"deletedRegion"`
+	InsertedContent sarifInsertedContent `json:"insertedContent"`
+}
+
+type sarifArtifactChange struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Replacements     []sarifReplacement    `json:"replacements"`
+}
+
+type sarifFix struct {
+	Description     sarifMessage          `json:"description"`
+	ArtifactChanges []sarifArtifactChange `json:"artifactChanges"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+	Fixes     []sarifFix      `json:"fixes,omitempty"`
+}
+
+func toSARIFResult(f finding) sarifResult {
+	fset := f.pkg.Fset
+	start, end := fset.Position(f.diag.Pos), fset.Position(f.diag.End)
+
+	res := sarifResult{
+		RuleID:  gothrow.Analyzer.Name,
+		Message: sarifMessage{Text: f.diag.Message},
+		Locations: []sarifLocation{{
+			PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: fileURI(start.Filename)},
+				Region: sarifRegion{
+					StartLine: start.Line, StartColumn: start.Column,
+					EndLine: end.Line, EndColumn: end.Column,
+				},
+			},
+		}},
+	}
+
+	if len(f.diag.SuggestedFixes) > 0 {
+		fix := f.diag.SuggestedFixes[0]
+		byFile := make(map[string][]sarifReplacement)
+		var order []string
+		for _, e := range fix.TextEdits {
+			es, ee := fset.Position(e.Pos), fset.Position(e.End)
+			uri := fileURI(es.Filename)
+			if _, ok := byFile[uri]; !ok {
+				order = append(order, uri)
+			}
+			byFile[uri] = append(byFile[uri], sarifReplacement{
+				DeletedRegion: sarifRegion{
+					StartLine: es.Line, StartColumn: es.Column,
+					EndLine: ee.Line, EndColumn: ee.Column,
+				},
+				InsertedContent: sarifInsertedContent{Text: string(e.NewText)},
+			})
+		}
+		var changes []sarifArtifactChange
+		for _, uri := range order {
+			changes = append(changes, sarifArtifactChange{
+				ArtifactLocation: sarifArtifactLocation{URI: uri},
+				Replacements:     byFile[uri],
+			})
+		}
+		res.Fixes = []sarifFix{{Description: sarifMessage{Text: fix.Message}, ArtifactChanges: changes}}
+	}
+
+	return res
+}
+
+func fileURI(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return "file://" + abs
+	}
+	return path
+}
+
+func emitSARIF(findings []finding) {
+	run := sarifRun{Tool: sarifTool{Driver: sarifDriver{Name: gothrow.Analyzer.Name}}, Results: []sarifResult{}}
+	for _, f := range findings {
+		run.Results = append(run.Results, toSARIFResult(f))
+	}
+
+	log := sarifLog{
+		Schema:  "https://raw.githubusercontent.com/oasis-tcs/sarif-spec/master/Schemata/sarif-schema-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(log); err != nil {
+		fmt.Fprintln(os.Stderr, "gothrow:", err)
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeFixture lays out a minimal module with three ignored-error
+// findings in one file, the shape that exposed the -fix+-format=json
+// snippet corruption: applyFixes's writes shifted every offset after
+// the first edit in the file, and snippet() went on reading the
+// (by-then-stale) positions straight off disk.
+func writeFixture(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module fixture\n\ngo 1.21\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	src := `package fixture
+
+import "os"
+
+func one() error {
+	os.Remove("a")
+	return nil
+}
+
+func two() error {
+	os.Remove("b")
+	return nil
+}
+
+func three() error {
+	os.Remove("c")
+	return nil
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "fixture.go"), []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+// TestApplyFixesDoesNotInvalidateSnippets round-trips analyze ->
+// computeSnippets -> applyFixes, the order main itself uses, and checks
+// that every finding's cached before/after still matches the statement
+// it was reported against, not a slice of whatever applyFixes's writes
+// left at that byte offset.
+func TestApplyFixesDoesNotInvalidateSnippets(t *testing.T) {
+	dir := writeFixture(t)
+
+	findings, err := analyze(dir)
+	if err != nil {
+		t.Fatalf("analyze: %v", err)
+	}
+	if len(findings) != 3 {
+		t.Fatalf("got %d findings, want 3", len(findings))
+	}
+
+	snippets := computeSnippets(findings)
+
+	if err := applyFixes(findings); err != nil {
+		t.Fatalf("applyFixes: %v", err)
+	}
+
+	wantBefore := []string{`os.Remove("a")`, `os.Remove("b")`, `os.Remove("c")`}
+	wantAfter := []string{
+		"err := os.Remove(\"a\")\n\tif err != nil {\n\treturn err\n}",
+		"err := os.Remove(\"b\")\n\tif err != nil {\n\treturn err\n}",
+		"err := os.Remove(\"c\")\n\tif err != nil {\n\treturn err\n}",
+	}
+	for i, snip := range snippets {
+		if snip.before != wantBefore[i] {
+			t.Errorf("finding %d: before = %q, want %q", i, snip.before, wantBefore[i])
+		}
+		if snip.after != wantAfter[i] {
+			t.Errorf("finding %d: after = %q, want %q", i, snip.after, wantAfter[i])
+		}
+	}
+
+	for i, f := range findings {
+		jd := toJSONDiagnostic(f, snippets[i])
+		if jd.Before != wantBefore[i] || jd.After != wantAfter[i] {
+			t.Errorf("finding %d: json diagnostic before/after = %q/%q, want %q/%q", i, jd.Before, jd.After, wantBefore[i], wantAfter[i])
+		}
+	}
+}
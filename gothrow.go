@@ -0,0 +1,721 @@
+// Package gothrow defines an Analyzer that reports assignments which
+// silently discard an error and suggests fixes that surface it instead.
+package gothrow
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+const Doc = `report ignored errors and suggest fixes that surface them
+
+gothrow flags assignments such as "v, _ := f()" or "_, _ = f()" that
+throw away an error return value, and calls such as "f()" used as a
+statement whose error result is dropped. Where it can, it offers a
+SuggestedFix that introduces "err" and checks it with an
+"if err != nil { ... }" block. By default that block returns the error
+from the enclosing function or, inside main, logs it with log.Fatalf;
+the -policy config file can select a different handler. A finding in a
+function that neither returns an error nor is main, and that no policy
+fits, is reported without a fix.`
+
+// Analyzer reports ignored errors and offers SuggestedFixes for them.
+var Analyzer = &analysis.Analyzer{
+	Name:     "gothrow",
+	Doc:      Doc,
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	for _, file := range pass.Files {
+		checkFile(pass, file)
+	}
+	return nil, nil
+}
+
+// modKind distinguishes the three shapes of statement checkFile acts on.
+type modKind int
+
+const (
+	kindIgnoredAssign modKind = iota // `v, _ := f()` / `_, _ = f()`
+	kindDemotion                     // a later `err := ...` that should become `err = ...`
+	kindBareCall                     // `f()` as a statement, dropping an error entirely
+)
+
+// modification describes one statement that gothrow wants to change,
+// along with the state needed to decide exactly how.
+type modification struct {
+	pos      token.Pos
+	kind     modKind
+	assign   *ast.AssignStmt // kindIgnoredAssign, kindDemotion
+	errIdx   int             // kindIgnoredAssign, kindBareCall
+	numRes   int             // kindBareCall
+	exprStmt *ast.ExprStmt   // kindBareCall
+}
+
+func checkFile(pass *analysis.Pass, file *ast.File) {
+	info := pass.TypesInfo
+
+	var mods []modification
+
+	// PASS 1: collect every statement that ignores an error, either by
+	// assignment or as a bare call, plus any `err :=` that might need to
+	// be demoted to `err =`.
+	astutil.Apply(file, func(cursor *astutil.Cursor) bool {
+		switch stmt := cursor.Node().(type) {
+		case *ast.AssignStmt:
+			if stmt.Tok != token.DEFINE && stmt.Tok != token.ASSIGN {
+				return true
+			}
+
+			if idx := getErrorIndex(stmt, info); idx != -1 && isIgnored(stmt.Lhs[idx]) {
+				mods = append(mods, modification{pos: stmt.Pos(), kind: kindIgnoredAssign, assign: stmt, errIdx: idx})
+				return true
+			}
+
+			if stmt.Tok == token.DEFINE && len(stmt.Lhs) == 1 {
+				if id, ok := stmt.Lhs[0].(*ast.Ident); ok && id.Name == "err" {
+					mods = append(mods, modification{pos: stmt.Pos(), kind: kindDemotion, assign: stmt})
+				}
+			}
+		case *ast.ExprStmt:
+			call, ok := stmt.X.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			if idx, n := errorIndexForCall(call, info); idx != -1 {
+				mods = append(mods, modification{pos: stmt.Pos(), kind: kindBareCall, exprStmt: stmt, errIdx: idx, numRes: n})
+			}
+		}
+
+		return true
+	}, nil)
+
+	if len(mods) == 0 {
+		return
+	}
+
+	sort.Slice(mods, func(i, j int) bool { return mods[i].pos < mods[j].pos })
+
+	// PASS 2: walk the same modifications in source order, tracking which
+	// enclosing function(s) we've already introduced `err` into, and turn
+	// each one into a Diagnostic with a SuggestedFix.
+	errIntroducedInFunc := make(map[ast.Node]bool)
+
+	// claimedImports tracks, across every mod in this file, which paths
+	// an earlier diagnostic already scheduled an importsEdit for: two
+	// findings in the same file needing the same new import must only
+	// insert it once, since RunWithSuggestedFixes (and `go vet -fix`)
+	// applies every diagnostic's edits to the same file and rejects
+	// duplicate zero-width insertions as corrupting the source.
+	claimedImports := make(map[string]bool)
+
+	for _, mod := range mods {
+		enclosingFunc := findEnclosingFunc(file, mod.pos)
+		if enclosingFunc == nil {
+			continue
+		}
+
+		var scopeStmt ast.Stmt = mod.assign
+		if mod.kind == kindBareCall {
+			scopeStmt = mod.exprStmt
+		}
+		scope := innermostScope(file, scopeStmt, info)
+
+		if mod.kind == kindDemotion {
+			isDeclaredInTypes := scope != nil && scope.Lookup("err") != nil
+			isDeclaredByUs := errIntroducedInFunc[enclosingFunc.node]
+
+			if isDeclaredByUs || isDeclaredInTypes {
+				pass.Report(analysis.Diagnostic{
+					Pos:     mod.assign.Pos(),
+					Message: "redundant `err :=` redeclares err already in scope",
+					SuggestedFixes: []analysis.SuggestedFix{{
+						Message: "Change `:=` to `=`",
+						TextEdits: []analysis.TextEdit{{
+							Pos:     mod.assign.Lhs[0].End(),
+							End:     mod.assign.Rhs[0].Pos(),
+							NewText: []byte(" = "),
+						}},
+					}},
+				})
+			}
+			errIntroducedInFunc[enclosingFunc.node] = true
+			continue
+		}
+
+		isErrAlreadyDeclared := errIntroducedInFunc[enclosingFunc.node] || (scope != nil && scope.Lookup("err") != nil)
+
+		if mod.kind == kindBareCall {
+			check, newImports := resolveErrorCheck(pass, file, enclosingFunc, mod.exprStmt.Pos())
+			if check == nil {
+				// enclosingFunc neither returns an error nor is main, and
+				// no policy fits it either, so there's no sound statement
+				// to splice in after introducing err. Rewriting the call
+				// into `err := ...` anyway would leave err declared and
+				// unused, so report the finding without a fix instead.
+				pass.Report(analysis.Diagnostic{
+					Pos:     mod.exprStmt.Pos(),
+					End:     mod.exprStmt.End(),
+					Message: "error returned by this call is ignored",
+				})
+				continue
+			}
+
+			tok := token.DEFINE
+			if isErrAlreadyDeclared {
+				tok = token.ASSIGN
+			} else {
+				errIntroducedInFunc[enclosingFunc.node] = true
+			}
+			pass.Report(bareCallDiagnostic(pass, file, mod.exprStmt, mod.errIdx, mod.numRes, tok, check, newImports, claimedImports))
+			continue
+		}
+
+		check, newImports := resolveErrorCheck(pass, file, enclosingFunc, mod.assign.Pos())
+		if check == nil {
+			// Same situation as kindBareCall above: enclosingFunc can't
+			// return an error, isn't main, and no policy fits it, so
+			// there's no sound statement to splice in after introducing
+			// err. Report the finding without a fix instead of rewriting
+			// the assignment into one that leaves err unused.
+			pass.Report(analysis.Diagnostic{
+				Pos:     mod.assign.Pos(),
+				End:     mod.assign.End(),
+				Message: "error returned by this call is ignored",
+			})
+			continue
+		}
+
+		tok := mod.assign.Tok
+		if tok == token.ASSIGN && !isErrAlreadyDeclared {
+			tok = token.DEFINE
+		} else if tok == token.DEFINE && isErrAlreadyDeclared {
+			if !anyOtherNewVariables(mod.assign, mod.errIdx, info) {
+				tok = token.ASSIGN
+			}
+		}
+		if tok == token.DEFINE && !isErrAlreadyDeclared {
+			errIntroducedInFunc[enclosingFunc.node] = true
+		}
+
+		pass.Report(ignoredErrorDiagnostic(pass, file, mod.assign, mod.errIdx, tok, check, newImports, claimedImports))
+	}
+}
+
+// ignoredErrorDiagnostic builds the Diagnostic and SuggestedFix for an
+// assignment that currently discards an error. check and newImports
+// come from resolveErrorCheck, already resolved by the caller so it can
+// skip the rewrite entirely when check is nil.
+func ignoredErrorDiagnostic(pass *analysis.Pass, file *ast.File, assign *ast.AssignStmt, errIdx int, tok token.Token, check ast.Stmt, newImports []string, claimedImports map[string]bool) analysis.Diagnostic {
+	indent := indentAt(pass.Fset, assign.Pos())
+
+	var edits []analysis.TextEdit
+
+	// Replace "<lhs>, <lhs>, ... :=|= " in one go so the rewritten `err`
+	// and the (possibly changed) token stay in sync.
+	names := make([]string, len(assign.Lhs))
+	for i, lhs := range assign.Lhs {
+		if i == errIdx {
+			names[i] = "err"
+			continue
+		}
+		names[i] = renderExpr(lhs)
+	}
+	edits = append(edits, analysis.TextEdit{
+		Pos:     assign.Lhs[0].Pos(),
+		End:     assign.Rhs[0].Pos(),
+		NewText: []byte(strings.Join(names, ", ") + " " + tok.String() + " "),
+	})
+
+	checkText := renderStmt(check)
+	edits = append(edits, analysis.TextEdit{
+		Pos:     assign.End(),
+		End:     assign.End(),
+		NewText: []byte("\n" + indent + checkText),
+	})
+
+	if len(newImports) > 0 {
+		if extra, ok := importsEdit(file, newImports, claimedImports); ok {
+			edits = append(edits, extra...)
+		}
+	}
+
+	return analysis.Diagnostic{
+		Pos:     assign.Pos(),
+		End:     assign.End(),
+		Message: "error returned by this call is ignored",
+		SuggestedFixes: []analysis.SuggestedFix{{
+			Message:   "Introduce err and check it",
+			TextEdits: edits,
+		}},
+	}
+}
+
+// bareCallDiagnostic builds the Diagnostic and SuggestedFix for a call
+// used as a statement that drops an error entirely, e.g.
+// "os.Chmod(path, mode)" or "io.Copy(dst, src)". It synthesizes fresh
+// blank identifiers for every discarded result and "err" for the error
+// one, turning the ExprStmt into an AssignStmt. check and newImports
+// come from resolveErrorCheck, already resolved by the caller so it can
+// skip the rewrite entirely when check is nil.
+func bareCallDiagnostic(pass *analysis.Pass, file *ast.File, exprStmt *ast.ExprStmt, errIdx, numResults int, tok token.Token, check ast.Stmt, newImports []string, claimedImports map[string]bool) analysis.Diagnostic {
+	indent := indentAt(pass.Fset, exprStmt.Pos())
+
+	names := make([]string, numResults)
+	for i := range names {
+		if i == errIdx {
+			names[i] = "err"
+		} else {
+			names[i] = "_"
+		}
+	}
+
+	edits := []analysis.TextEdit{{
+		Pos:     exprStmt.Pos(),
+		End:     exprStmt.Pos(),
+		NewText: []byte(strings.Join(names, ", ") + " " + tok.String() + " "),
+	}}
+
+	checkText := renderStmt(check)
+	edits = append(edits, analysis.TextEdit{
+		Pos:     exprStmt.End(),
+		End:     exprStmt.End(),
+		NewText: []byte("\n" + indent + checkText),
+	})
+
+	if len(newImports) > 0 {
+		if extra, ok := importsEdit(file, newImports, claimedImports); ok {
+			edits = append(edits, extra...)
+		}
+	}
+
+	return analysis.Diagnostic{
+		Pos:     exprStmt.Pos(),
+		End:     exprStmt.End(),
+		Message: "error returned by this call is ignored",
+		SuggestedFixes: []analysis.SuggestedFix{{
+			Message:   "Introduce err and check it",
+			TextEdits: edits,
+		}},
+	}
+}
+
+// importsEdit returns the TextEdits that add any of paths not already
+// imported by file, nor already claimed by an earlier call for this
+// same file, to its import declaration, synthesizing a well-formed
+// `import (...)` block whether file currently has no imports at all, a
+// single unparenthesized import, or an existing parenthesized block.
+// Every case is built from zero-width insertions that add text without
+// replacing any existing import text, so edits from two different
+// findings in the same file never overlap at the text level; claimed
+// is what keeps them from both scheduling the same insertion, since the
+// go/analysis drivers that apply SuggestedFixes (RunWithSuggestedFixes,
+// `go vet -fix`) reject two edits at the same zero-width position as
+// ambiguous rather than silently merging them.
+func importsEdit(file *ast.File, paths []string, claimed map[string]bool) ([]analysis.TextEdit, bool) {
+	var missing []string
+	for _, path := range paths {
+		if claimed[path] {
+			continue
+		}
+		imported := false
+		for _, imp := range file.Imports {
+			if strings.Trim(imp.Path.Value, `"`) == path {
+				imported = true
+				break
+			}
+		}
+		if !imported {
+			missing = append(missing, path)
+		}
+	}
+	for _, path := range missing {
+		claimed[path] = true
+	}
+	if len(missing) == 0 {
+		return nil, false
+	}
+
+	decl := lastImportDecl(file)
+
+	if decl == nil {
+		var text strings.Builder
+		text.WriteString("\n\nimport (")
+		for _, path := range missing {
+			text.WriteString("\n\t\"" + path + "\"")
+		}
+		text.WriteString("\n)")
+		return []analysis.TextEdit{{
+			Pos:     file.Name.End(),
+			End:     file.Name.End(),
+			NewText: []byte(text.String()),
+		}}, true
+	}
+
+	if !decl.Lparen.IsValid() {
+		// Wrap the lone existing import in parens and append the missing
+		// ones, without ever replacing the existing import's own text:
+		// one insertion opens the block just before it, another closes
+		// the block just after it.
+		spec := decl.Specs[0].(*ast.ImportSpec)
+		var text strings.Builder
+		for _, path := range missing {
+			text.WriteString("\n\t\"" + path + "\"")
+		}
+		text.WriteString("\n)")
+		return []analysis.TextEdit{
+			{Pos: spec.Pos(), End: spec.Pos(), NewText: []byte("(\n\t")},
+			{Pos: spec.End(), End: spec.End(), NewText: []byte(text.String())},
+		}, true
+	}
+
+	var text strings.Builder
+	for _, path := range missing {
+		text.WriteString("\n\t\"" + path + "\"")
+	}
+	pos := decl.Lparen + 1
+	if n := len(decl.Specs); n > 0 {
+		pos = decl.Specs[n-1].End()
+	}
+	return []analysis.TextEdit{{
+		Pos:     pos,
+		End:     pos,
+		NewText: []byte(text.String()),
+	}}, true
+}
+
+// lastImportDecl returns the last import declaration in file, whether
+// it's a single `import "x"` or a parenthesized `import (...)` block,
+// or nil if file has none.
+func lastImportDecl(file *ast.File) *ast.GenDecl {
+	var last *ast.GenDecl
+	for _, decl := range file.Decls {
+		if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.IMPORT {
+			last = gen
+		}
+	}
+	return last
+}
+
+func renderStmt(stmt ast.Stmt) string {
+	var buf bytes.Buffer
+	if err := format.Node(&buf, token.NewFileSet(), stmt); err != nil {
+		return fmt.Sprintf("/* failed to render suggested fix: %v */", err)
+	}
+	return buf.String()
+}
+
+func renderExpr(expr ast.Expr) string {
+	var buf bytes.Buffer
+	if err := format.Node(&buf, token.NewFileSet(), expr); err != nil {
+		return fmt.Sprintf("/* failed to render %T */", expr)
+	}
+	return buf.String()
+}
+
+func indentAt(fset *token.FileSet, pos token.Pos) string {
+	col := fset.Position(pos).Column
+	if col <= 1 {
+		return ""
+	}
+	return strings.Repeat("\t", col-1)
+}
+
+func isErrorType(t types.Type) bool {
+	if t == nil {
+		return false
+	}
+	errorInterface, ok := types.Universe.Lookup("error").Type().Underlying().(*types.Interface)
+	if !ok {
+		return false
+	}
+	return types.Implements(t, errorInterface)
+}
+
+// funcScope identifies the innermost function-like node (*ast.FuncDecl
+// or *ast.FuncLit) enclosing some position, so that ignored errors found
+// inside methods, closures, and nested function literals are attributed
+// to the right signature rather than to the top-level decl around them.
+type funcScope struct {
+	node   ast.Node // *ast.FuncDecl or *ast.FuncLit
+	typ    *ast.FuncType
+	isMain bool
+}
+
+func canReturnError(fn *funcScope) bool {
+	if fn.typ.Results == nil {
+		return false
+	}
+	if len(fn.typ.Results.List) == 0 {
+		return false
+	}
+	lastResult := fn.typ.Results.List[len(fn.typ.Results.List)-1]
+	if id, ok := lastResult.Type.(*ast.Ident); ok {
+		return id.Name == "error"
+	}
+	return false
+}
+
+// findEnclosingFunc walks the path to pos and returns the innermost
+// *ast.FuncDecl or *ast.FuncLit enclosing it.
+func findEnclosingFunc(file *ast.File, pos token.Pos) *funcScope {
+	path, _ := astutil.PathEnclosingInterval(file, pos, pos)
+	for _, n := range path {
+		switch fn := n.(type) {
+		case *ast.FuncLit:
+			return &funcScope{node: fn, typ: fn.Type}
+		case *ast.FuncDecl:
+			return &funcScope{node: fn, typ: fn.Type, isMain: fn.Recv == nil && fn.Name.Name == "main"}
+		}
+	}
+	return nil
+}
+
+// createErrorCheck builds the "if err != nil { return ... }" block for
+// enclosingFunc, using its real result types (rather than guessing from
+// the AST) to synthesize correct zero values. It also returns the
+// packages that need to be imported for those zero values to resolve.
+func createErrorCheck(pass *analysis.Pass, file *ast.File, enclosingFunc *funcScope) (*ast.IfStmt, []*types.Package) {
+	ifStmt := &ast.IfStmt{
+		Cond: &ast.BinaryExpr{
+			X:  &ast.Ident{Name: "err"},
+			Op: token.NEQ,
+			Y:  &ast.Ident{Name: "nil"},
+		},
+		Body: &ast.BlockStmt{},
+	}
+
+	retStmt := &ast.ReturnStmt{}
+	var needed []*types.Package
+
+	if sig := funcSignature(pass, enclosingFunc); sig != nil {
+		results := sig.Results()
+		qf, imports := qualifierFor(pass.Pkg, file)
+		for i := 0; i < results.Len(); i++ {
+			retStmt.Results = append(retStmt.Results, zeroValueFor(results.At(i).Type(), qf))
+		}
+		if results.Len() > 0 && isErrorType(results.At(results.Len()-1).Type()) {
+			retStmt.Results[results.Len()-1] = &ast.Ident{Name: "err"}
+		}
+		needed = *imports
+	}
+
+	ifStmt.Body.List = []ast.Stmt{retStmt}
+
+	return ifStmt, needed
+}
+
+// funcSignature resolves the go/types signature of a funcScope, whether
+// it's a declared function/method or a function literal.
+func funcSignature(pass *analysis.Pass, fn *funcScope) *types.Signature {
+	switch n := fn.node.(type) {
+	case *ast.FuncDecl:
+		obj := pass.TypesInfo.Defs[n.Name]
+		if obj == nil {
+			return nil
+		}
+		sig, _ := obj.Type().(*types.Signature)
+		return sig
+	case *ast.FuncLit:
+		tv, ok := pass.TypesInfo.Types[n]
+		if !ok {
+			return nil
+		}
+		sig, _ := tv.Type.(*types.Signature)
+		return sig
+	}
+	return nil
+}
+
+// qualifierFor returns a types.Qualifier that prints packages other than
+// pkg by the local name they're already imported under in file, and
+// records any package that isn't imported yet so the caller can add it.
+func qualifierFor(pkg *types.Package, file *ast.File) (types.Qualifier, *[]*types.Package) {
+	var needed []*types.Package
+	seen := make(map[string]bool)
+
+	qf := func(p *types.Package) string {
+		if p == pkg {
+			return ""
+		}
+		for _, imp := range file.Imports {
+			if strings.Trim(imp.Path.Value, `"`) == p.Path() {
+				if imp.Name != nil {
+					return imp.Name.Name
+				}
+				return p.Name()
+			}
+		}
+		if !seen[p.Path()] {
+			seen[p.Path()] = true
+			needed = append(needed, p)
+		}
+		return p.Name()
+	}
+
+	return qf, &needed
+}
+
+// zeroValueFor derives the zero-value expression for t, qualifying any
+// named types from other packages with qf.
+func zeroValueFor(t types.Type, qf types.Qualifier) ast.Expr {
+	if _, ok := t.(*types.TypeParam); ok {
+		return &ast.StarExpr{
+			X: &ast.CallExpr{
+				Fun:  ast.NewIdent("new"),
+				Args: []ast.Expr{typeExprFor(t, qf)},
+			},
+		}
+	}
+
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		switch {
+		case u.Info()&types.IsBoolean != 0:
+			return ast.NewIdent("false")
+		case u.Info()&types.IsString != 0:
+			return &ast.BasicLit{Kind: token.STRING, Value: `""`}
+		case u.Info()&types.IsComplex != 0:
+			return &ast.BinaryExpr{
+				X:  &ast.BasicLit{Kind: token.INT, Value: "0"},
+				Op: token.ADD,
+				Y:  &ast.BasicLit{Kind: token.IMAG, Value: "0i"},
+			}
+		case u.Info()&types.IsNumeric != 0:
+			return &ast.BasicLit{Kind: token.INT, Value: "0"}
+		default:
+			return ast.NewIdent("nil")
+		}
+	case *types.Pointer, *types.Slice, *types.Map, *types.Chan, *types.Interface, *types.Signature:
+		return ast.NewIdent("nil")
+	case *types.Struct, *types.Array:
+		return &ast.CompositeLit{Type: typeExprFor(t, qf)}
+	default:
+		return ast.NewIdent("nil")
+	}
+}
+
+// typeExprFor renders t (qualified by qf) and reparses it into an
+// ast.Expr suitable for splicing into a synthesized statement.
+func typeExprFor(t types.Type, qf types.Qualifier) ast.Expr {
+	expr, err := parser.ParseExpr(types.TypeString(t, qf))
+	if err != nil {
+		return ast.NewIdent("nil")
+	}
+	return expr
+}
+
+func createErrorCheckForMain() *ast.IfStmt {
+	return &ast.IfStmt{
+		Cond: &ast.BinaryExpr{
+			X:  &ast.Ident{Name: "err"},
+			Op: token.NEQ,
+			Y:  &ast.Ident{Name: "nil"},
+		},
+		Body: &ast.BlockStmt{
+			List: []ast.Stmt{
+				&ast.ExprStmt{
+					X: &ast.CallExpr{
+						Fun: &ast.SelectorExpr{
+							X:   &ast.Ident{Name: "log"},
+							Sel: &ast.Ident{Name: "Fatalf"},
+						},
+						Args: []ast.Expr{
+							&ast.BasicLit{Kind: token.STRING, Value: `"error: %v"`},
+							&ast.Ident{Name: "err"},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func getErrorIndex(assign *ast.AssignStmt, info *types.Info) int {
+	if len(assign.Rhs) != 1 {
+		return -1
+	}
+	call, ok := assign.Rhs[0].(*ast.CallExpr)
+	if !ok {
+		return -1
+	}
+	idx, _ := errorIndexForCall(call, info)
+	return idx
+}
+
+// errorIndexForCall reports the index of call's error result (or -1 if
+// it has none) along with its total number of results, so callers that
+// need to rebuild the full result list (e.g. for a bare call statement)
+// don't have to re-derive the signature. A call with a single result
+// reports that result's type directly rather than as a *types.Tuple, so
+// that case is handled separately.
+func errorIndexForCall(call *ast.CallExpr, info *types.Info) (errIdx, numResults int) {
+	callType := info.TypeOf(call)
+	if callType == nil {
+		return -1, 0
+	}
+
+	tuple, ok := callType.(*types.Tuple)
+	if !ok {
+		if isErrorType(callType) {
+			return 0, 1
+		}
+		return -1, 1
+	}
+
+	errIdx = -1
+	for i := 0; i < tuple.Len(); i++ {
+		if isErrorType(tuple.At(i).Type()) {
+			errIdx = i
+			break
+		}
+	}
+	return errIdx, tuple.Len()
+}
+
+func anyOtherNewVariables(assign *ast.AssignStmt, errIdx int, info *types.Info) bool {
+	for i, lhsExpr := range assign.Lhs {
+		if i == errIdx {
+			continue
+		}
+		if id, ok := lhsExpr.(*ast.Ident); ok {
+			if info.Defs[id] != nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// innermostScope finds the narrowest scope enclosing a statement.
+func innermostScope(file *ast.File, stmt ast.Stmt, info *types.Info) *types.Scope {
+	path, _ := astutil.PathEnclosingInterval(file, stmt.Pos(), stmt.End())
+	if path == nil {
+		return nil
+	}
+
+	for i := len(path) - 1; i >= 0; i-- {
+		if scope, ok := info.Scopes[path[i]]; ok {
+			return scope
+		}
+	}
+	return nil
+}
+
+func isIgnored(e ast.Expr) bool {
+	id, ok := e.(*ast.Ident)
+	return ok && id.Name == "_"
+}
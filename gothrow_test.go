@@ -0,0 +1,57 @@
+package gothrow
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+// TestAnalyzer covers the default "return" policy across the shapes
+// that have broken in the past: a bare call, an ignored assignment, a
+// function that can't be given a fix at all, a closure, a generic
+// function, and a call into another package.
+func TestAnalyzer(t *testing.T) {
+	usePolicy(t, "")
+	analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), Analyzer, "a", "b")
+}
+
+// TestAnalyzerWrapPolicy covers the "wrap" policy's import handling: two
+// findings in one file, with a single unparenthesized import, each need
+// to add "fmt" — the case that used to corrupt the file before
+// importsEdit switched to composable insertions.
+func TestAnalyzerWrapPolicy(t *testing.T) {
+	usePolicy(t, filepath.Join(analysistest.TestData(), "policy-wrap-c.yaml"))
+	analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), Analyzer, "c")
+}
+
+// TestAnalyzerPanicPolicy covers the "panic" policy.
+func TestAnalyzerPanicPolicy(t *testing.T) {
+	usePolicy(t, filepath.Join(analysistest.TestData(), "policy-panic-d.yaml"))
+	analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), Analyzer, "d")
+}
+
+// TestAnalyzerCustomPolicy covers the "custom" policy, including the
+// fix for resolveErrorCheck inserting a Template's statement
+// unconditionally instead of guarding it with "if err != nil { ... }".
+func TestAnalyzerCustomPolicy(t *testing.T) {
+	usePolicy(t, filepath.Join(analysistest.TestData(), "policy-custom-e.yaml"))
+	analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), Analyzer, "e")
+}
+
+// usePolicy points the -policy flag at path and forces the next
+// loadedConfig call to read it, overriding the sync.Once that normally
+// caches the policy for the life of the process: each test here wants
+// its own policy, not whatever an earlier test already loaded. An empty
+// path points at a nonexistent file, so loadedConfig falls back to the
+// default "return" policy for every function.
+func usePolicy(t *testing.T, path string) {
+	t.Helper()
+	if path == "" {
+		path = filepath.Join(t.TempDir(), "missing.gothrow.yaml")
+	}
+	*policyPath = path
+	configOnce = sync.Once{}
+	config = nil
+}
@@ -0,0 +1,302 @@
+package gothrow
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"regexp"
+	"sync"
+	"text/template"
+
+	"golang.org/x/tools/go/analysis"
+	"gopkg.in/yaml.v3"
+)
+
+// policyPath is the -policy flag: the path to a YAML config selecting,
+// per function, what gothrow should insert after introducing err.
+var policyPath *string
+
+func init() {
+	policyPath = Analyzer.Flags.String("policy", ".gothrow.yaml", "path to a YAML policy config file selecting how ignored errors are handled")
+}
+
+// PolicyKind is the code gothrow inserts after introducing err.
+// PolicyReturn is the tool's historical behavior: return the error (or
+// log.Fatalf it, inside main). An empty or unrecognized PolicyKind is
+// handled the same way as PolicyReturn.
+type PolicyKind string
+
+const (
+	PolicyReturn      PolicyKind = "return"
+	PolicyWrap        PolicyKind = "wrap"
+	PolicyLogContinue PolicyKind = "log-continue"
+	PolicyPanic       PolicyKind = "panic"
+	PolicyCustom      PolicyKind = "custom"
+)
+
+// Rule selects a Policy for the functions it matches. The first Rule in
+// Config.Rules that matches wins; a zero-value field always matches.
+type Rule struct {
+	Package      string     `yaml:"package"`       // glob matched against the importpath, e.g. "internal/*"
+	Func         string     `yaml:"func"`          // regex matched against the function (or method) name
+	ReturnsError *bool      `yaml:"returns_error"` // match only functions that do/don't already return an error
+	Policy       PolicyKind `yaml:"policy"`
+	Template     string     `yaml:"template"` // Go text/template source for the body of "if err != nil { ... }", used when Policy is PolicyCustom
+	Imports      []string   `yaml:"imports"`  // packages Template references, e.g. "fmt"; only needed for PolicyCustom
+}
+
+// Config is the root of a .gothrow.yaml policy file.
+type Config struct {
+	Rules []Rule `yaml:"rules"`
+}
+
+func (r Rule) matches(pkgPath, funcName string, returnsError bool) bool {
+	if r.Package != "" {
+		if ok, err := path.Match(r.Package, pkgPath); err != nil || !ok {
+			return false
+		}
+	}
+	if r.Func != "" {
+		re, err := regexp.Compile(r.Func)
+		if err != nil || !re.MatchString(funcName) {
+			return false
+		}
+	}
+	if r.ReturnsError != nil && *r.ReturnsError != returnsError {
+		return false
+	}
+	return true
+}
+
+// LoadConfig reads and parses a .gothrow.yaml policy file. A missing
+// file is not an error: it just means no rules apply and every
+// function gets the default return policy.
+func LoadConfig(file string) (*Config, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &Config{}, nil
+		}
+		return nil, fmt.Errorf("gothrow: reading policy %s: %w", file, err)
+	}
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("gothrow: parsing policy %s: %w", file, err)
+	}
+	return &cfg, nil
+}
+
+var (
+	configOnce sync.Once
+	config     *Config
+)
+
+// loadedConfig loads the policy named by -policy exactly once per
+// process, regardless of how many packages this run analyzes. A config
+// that fails to load is reported to stderr and treated as empty, so a
+// bad .gothrow.yaml degrades to the default policy rather than aborting
+// the whole run.
+func loadedConfig() *Config {
+	configOnce.Do(func() {
+		cfg, err := LoadConfig(*policyPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			cfg = &Config{}
+		}
+		config = cfg
+	})
+	return config
+}
+
+func ruleFor(cfg *Config, pkgPath, funcName string, returnsError bool) *Rule {
+	for i := range cfg.Rules {
+		if cfg.Rules[i].matches(pkgPath, funcName, returnsError) {
+			return &cfg.Rules[i]
+		}
+	}
+	return nil
+}
+
+// funcName returns the name used to match Rule.Func against
+// enclosingFunc: the declared name for a FuncDecl (method or plain
+// function), or a fixed placeholder for an anonymous FuncLit.
+func funcName(fn *funcScope) string {
+	switch n := fn.node.(type) {
+	case *ast.FuncDecl:
+		return n.Name.Name
+	case *ast.FuncLit:
+		return "func literal"
+	}
+	return ""
+}
+
+// resolveErrorCheck is the single dispatcher that decides what
+// statement to splice in after a newly introduced err, driven by the
+// Policy resolved for enclosingFunc from the loaded Config. It replaces
+// the old createErrorCheck/createErrorCheckForMain split: both are now
+// just the default PolicyReturn branch.
+func resolveErrorCheck(pass *analysis.Pass, file *ast.File, enclosingFunc *funcScope, pos token.Pos) (ast.Stmt, []string) {
+	cfg := loadedConfig()
+	name := funcName(enclosingFunc)
+	rule := ruleFor(cfg, pass.Pkg.Path(), name, canReturnError(enclosingFunc))
+
+	policy := PolicyReturn
+	if rule != nil {
+		policy = rule.Policy
+	}
+
+	switch policy {
+	case PolicyWrap:
+		if canReturnError(enclosingFunc) {
+			return createWrapCheck(pass, file, enclosingFunc, name)
+		}
+	case PolicyLogContinue:
+		return createLogContinueCheck(), []string{"log/slog"}
+	case PolicyPanic:
+		return createPanicCheck(), nil
+	case PolicyCustom:
+		if rule != nil && rule.Template != "" {
+			if stmt, err := createCustomCheck(rule.Template, name, pass.Fset.Position(pos).String()); err == nil {
+				// A custom Template can reference packages gothrow has no
+				// way to discover by inspecting the generated code (e.g.
+				// fmt.Errorf), so the rule must declare them itself via
+				// imports: rather than relying on createErrorCheck's
+				// type-directed import resolution.
+				return wrapInErrCheck(stmt), rule.Imports
+			} else {
+				fmt.Fprintln(os.Stderr, err)
+			}
+		}
+	}
+
+	// PolicyReturn, or a policy that doesn't fit this function's shape
+	// (e.g. "wrap" on a function with no error result), falls back to
+	// the historical default.
+	if enclosingFunc.isMain {
+		return createErrorCheckForMain(), []string{"log"}
+	}
+	if canReturnError(enclosingFunc) {
+		ifStmt, needed := createErrorCheck(pass, file, enclosingFunc)
+		paths := make([]string, len(needed))
+		for i, p := range needed {
+			paths[i] = p.Path()
+		}
+		return ifStmt, paths
+	}
+	return nil, nil
+}
+
+// createWrapCheck builds "if err != nil { return ..., fmt.Errorf("<funcName>: %w", err) }".
+func createWrapCheck(pass *analysis.Pass, file *ast.File, enclosingFunc *funcScope, name string) (*ast.IfStmt, []string) {
+	ifStmt, needed := createErrorCheck(pass, file, enclosingFunc)
+	paths := make([]string, len(needed))
+	for i, p := range needed {
+		paths[i] = p.Path()
+	}
+
+	retStmt := ifStmt.Body.List[0].(*ast.ReturnStmt)
+	if len(retStmt.Results) > 0 {
+		last := len(retStmt.Results) - 1
+		retStmt.Results[last] = &ast.CallExpr{
+			Fun: &ast.SelectorExpr{X: ast.NewIdent("fmt"), Sel: ast.NewIdent("Errorf")},
+			Args: []ast.Expr{
+				&ast.BasicLit{Kind: token.STRING, Value: fmt.Sprintf("%q", name+": %w")},
+				ast.NewIdent("err"),
+			},
+		}
+		paths = append(paths, "fmt")
+	}
+
+	return ifStmt, paths
+}
+
+func createLogContinueCheck() *ast.IfStmt {
+	return &ast.IfStmt{
+		Cond: &ast.BinaryExpr{X: ast.NewIdent("err"), Op: token.NEQ, Y: ast.NewIdent("nil")},
+		Body: &ast.BlockStmt{
+			List: []ast.Stmt{
+				&ast.ExprStmt{
+					X: &ast.CallExpr{
+						Fun: &ast.SelectorExpr{X: ast.NewIdent("slog"), Sel: ast.NewIdent("Error")},
+						Args: []ast.Expr{
+							&ast.BasicLit{Kind: token.STRING, Value: `"error"`},
+							&ast.BasicLit{Kind: token.STRING, Value: `"err"`},
+							ast.NewIdent("err"),
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// wrapInErrCheck splices stmt into "if err != nil { stmt }", the same
+// guard every other policy builds for itself. A Template only supplies
+// the body of that guard, never the condition, so a template cannot
+// forget the err != nil check and fire on every call.
+func wrapInErrCheck(stmt ast.Stmt) *ast.IfStmt {
+	body, ok := stmt.(*ast.BlockStmt)
+	if !ok {
+		body = &ast.BlockStmt{List: []ast.Stmt{stmt}}
+	}
+	return &ast.IfStmt{
+		Cond: &ast.BinaryExpr{X: ast.NewIdent("err"), Op: token.NEQ, Y: ast.NewIdent("nil")},
+		Body: body,
+	}
+}
+
+func createPanicCheck() *ast.IfStmt {
+	return &ast.IfStmt{
+		Cond: &ast.BinaryExpr{X: ast.NewIdent("err"), Op: token.NEQ, Y: ast.NewIdent("nil")},
+		Body: &ast.BlockStmt{
+			List: []ast.Stmt{
+				&ast.ExprStmt{X: &ast.CallExpr{Fun: ast.NewIdent("panic"), Args: []ast.Expr{ast.NewIdent("err")}}},
+			},
+		},
+	}
+}
+
+// customCheckData is what a PolicyCustom Template is executed against.
+// The template supplies only the statement(s) to run once err is known
+// non-nil: resolveErrorCheck wraps the result in "if err != nil { ... }"
+// itself, so the template must not (and need not) test err again.
+type customCheckData struct {
+	Func string // name of the enclosing function
+	Err  string // identifier holding the error, always "err"
+	Pos  string // "file:line:column" of the statement being rewritten
+}
+
+func createCustomCheck(tmplSrc, funcName, pos string) (ast.Stmt, error) {
+	tmpl, err := template.New("gothrow-policy").Parse(tmplSrc)
+	if err != nil {
+		return nil, fmt.Errorf("gothrow: parsing custom policy template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	data := customCheckData{Func: funcName, Err: "err", Pos: pos}
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("gothrow: executing custom policy template: %w", err)
+	}
+
+	return parseStmt(buf.String())
+}
+
+// parseStmt parses src as Go statement(s) by wrapping it in a throwaway
+// function body, returning a single *ast.BlockStmt if it parsed to more
+// than one statement.
+func parseStmt(src string) (ast.Stmt, error) {
+	wrapped := "package p\nfunc _() {\n" + src + "\n}\n"
+	file, err := parser.ParseFile(token.NewFileSet(), "", wrapped, 0)
+	if err != nil {
+		return nil, fmt.Errorf("gothrow: parsing custom policy snippet: %w", err)
+	}
+	body := file.Decls[0].(*ast.FuncDecl).Body
+	if len(body.List) == 1 {
+		return body.List[0], nil
+	}
+	return body, nil
+}
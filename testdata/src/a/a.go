@@ -0,0 +1,47 @@
+// Package a is a fixture exercising gothrow's core diagnostics: bare
+// calls and ignored assignments, inside a closure and a generic
+// function, across a package boundary, and in a function that can't be
+// given a fix at all.
+package a
+
+import (
+	"os"
+
+	"b"
+)
+
+func bareCall() error {
+	os.Remove("x") // want "error returned by this call is ignored"
+	return nil
+}
+
+func ignoredAssign() error {
+	_, _ = os.ReadFile("x") // want "error returned by this call is ignored"
+	return nil
+}
+
+// unfixable neither returns an error nor is main, so gothrow can report
+// the finding but has no sound statement to splice in after
+// introducing err.
+func unfixable() {
+	_, _ = os.ReadFile("x") // want "error returned by this call is ignored"
+}
+
+func closure() error {
+	fn := func() error {
+		os.Remove("x") // want "error returned by this call is ignored"
+		return nil
+	}
+	return fn()
+}
+
+func Generic[T any]() (T, error) {
+	os.Remove("x") // want "error returned by this call is ignored"
+	var zero T
+	return zero, nil
+}
+
+func crossPackage() (b.Result, error) {
+	_, _ = b.DoThing() // want "error returned by this call is ignored"
+	return b.Result{}, nil
+}
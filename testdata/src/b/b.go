@@ -0,0 +1,15 @@
+// Package b is a fixture cross-package dependency for the gothrow
+// analyzer's own tests in package a: it gives them a non-builtin,
+// struct-typed zero value to qualify across a package boundary.
+package b
+
+// Result is returned alongside an error by DoThing.
+type Result struct {
+	Name string
+}
+
+// DoThing returns a Result and an error, never an error in practice,
+// so callers in the test fixtures are free to ignore it.
+func DoThing() (Result, error) {
+	return Result{}, nil
+}
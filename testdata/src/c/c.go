@@ -0,0 +1,18 @@
+// Package c exercises the "wrap" policy's import handling against a
+// file with a single unparenthesized import: two findings in one file,
+// each needing to add "fmt", must collapse into a single added import
+// rather than corrupting the file or duplicating it — the case
+// mergeEdits guards against in applyFixes.
+package c
+
+import "os"
+
+func one() error {
+	os.Remove("x") // want "error returned by this call is ignored"
+	return nil
+}
+
+func two() error {
+	os.Remove("y") // want "error returned by this call is ignored"
+	return nil
+}
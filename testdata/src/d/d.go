@@ -0,0 +1,10 @@
+// Package d exercises the "panic" policy: a bare call's ignored error
+// should panic rather than being returned.
+package d
+
+import "os"
+
+func one() error {
+	os.Remove("x") // want "error returned by this call is ignored"
+	return nil
+}
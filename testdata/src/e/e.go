@@ -0,0 +1,12 @@
+// Package e exercises the "custom" policy: the rule's own Template
+// supplies the statement to run, and resolveErrorCheck must still wrap
+// it in "if err != nil { ... }" itself rather than splicing it in
+// unconditionally.
+package e
+
+import "os"
+
+func one() error {
+	os.Remove("x") // want "error returned by this call is ignored"
+	return nil
+}